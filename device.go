@@ -0,0 +1,59 @@
+// ***************************************************************************
+//
+//  Copyright 2019 David (Dizzy) Smith, dizzyd@dizzyd.com
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+// ***************************************************************************
+package streamdeck
+
+import (
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// v2 protocol (XL, MK.2, Plus) feature report ids/layout for brightness,
+// serial number and firmware version queries.
+const (
+	v2BrightnessReportId = 0x03
+	v2BrightnessCommand  = 0x08
+
+	v2SerialReportId = 0x06
+	v2SerialLength   = 32
+	v2SerialOffset   = 2
+
+	v2FirmwareReportId = 0x05
+	v2FirmwareLength   = 32
+	v2FirmwareOffset   = 6
+)
+
+// clampPercent caps percent at 100; the device firmware doesn't otherwise
+// reject an out-of-range brightness value.
+func clampPercent(percent uint8) uint8 {
+	if percent > 100 {
+		return 100
+	}
+	return percent
+}
+
+// readFeatureString issues a GET_FEATURE request for reportId and returns
+// the ASCII string found at offset, trimmed of trailing NUL padding.
+// ReadFeature always returns a length-byte slice on success, so offset
+// (always < length at every call site) is guaranteed to be in range.
+func readFeatureString(deck *streamDeckBase, reportId byte, length, offset int) (string, error) {
+	report, err := deck.device.ReadFeature(reportId, length)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read feature report 0x%02x", reportId)
+	}
+
+	return strings.TrimRight(string(report[offset:]), "\x00"), nil
+}
@@ -0,0 +1,207 @@
+// ***************************************************************************
+//
+//  Copyright 2019 David (Dizzy) Smith, dizzyd@dizzyd.com
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+// ***************************************************************************
+package streamdeck
+
+import "time"
+
+// KeyEventType identifies the kind of transition a KeyEvent describes.
+type KeyEventType int
+
+const (
+	// KeyDown is emitted the frame a key transitions from released to pressed
+	KeyDown KeyEventType = iota
+
+	// KeyUp is emitted the frame a key transitions from pressed to released
+	KeyUp
+
+	// KeyChord is emitted alongside KeyDown when two or more keys are held
+	// within the configured chord window; Keys holds every key currently held
+	KeyChord
+)
+
+// KeyEvent describes a single key transition, or a chord of keys held
+// together within the debounce window.
+type KeyEvent struct {
+	Type KeyEventType
+	Key  byte
+	Keys []byte
+}
+
+// defaultChordWindow is how close together key-downs must land to be
+// reported as a KeyChord.
+const defaultChordWindow = 150 * time.Millisecond
+
+// keyStateReader is implemented by each model to read the current per-key
+// pressed state from the device. ok is false when the read timed out, or
+// the report wasn't a key-state report, without anything to diff.
+type keyStateReader interface {
+	readKeyStates(timeout int) (states []bool, ok bool, err error)
+}
+
+// SetChordWindow sets how close in time multiple key-downs must land to be
+// reported as a KeyChord event. The default is 150ms.
+func (deck *streamDeckBase) SetChordWindow(d time.Duration) {
+	deck.mu.Lock()
+	defer deck.mu.Unlock()
+	deck.chordWindow = d
+}
+
+// startEventLoop starts a background goroutine (if not already running)
+// reading key states via reader and returns a channel of KeyEvent. The
+// legacy handler API (SetKeyHandler/SetGlobalKeyHandler) is dispatched from
+// the same goroutine, so both styles of consuming key presses coexist.
+func (deck *streamDeckBase) startEventLoop(reader keyStateReader) <-chan KeyEvent {
+	deck.mu.Lock()
+	defer deck.mu.Unlock()
+
+	if deck.events != nil {
+		return deck.events
+	}
+
+	deck.events = make(chan KeyEvent, 16)
+	deck.stopCh = make(chan struct{})
+
+	deck.wg.Add(1)
+	go func(stopCh chan struct{}) {
+		defer deck.wg.Done()
+		for {
+			select {
+			case <-stopCh:
+				return
+			default:
+				deck.pollOnce(reader, 100)
+			}
+		}
+	}(deck.stopCh)
+
+	return deck.events
+}
+
+// stopEventLoop stops the goroutine started by startEventLoop, if running.
+func (deck *streamDeckBase) stopEventLoop() error {
+	deck.mu.Lock()
+	if deck.stopCh == nil {
+		deck.mu.Unlock()
+		return nil
+	}
+	close(deck.stopCh)
+	deck.mu.Unlock()
+
+	deck.wg.Wait()
+
+	deck.mu.Lock()
+	close(deck.events)
+	deck.events = nil
+	deck.stopCh = nil
+	deck.mu.Unlock()
+
+	return nil
+}
+
+// processKeyStates performs a single blocking read (up to timeout
+// milliseconds), diffs it against the last known state, dispatches the
+// legacy handler API and emits any resulting KeyEvents. It backs both
+// ProcessEvents and the background loop started by Events.
+func (deck *streamDeckBase) processKeyStates(reader keyStateReader, timeout int) error {
+	return deck.pollOnce(reader, timeout)
+}
+
+func (deck *streamDeckBase) pollOnce(reader keyStateReader, timeout int) error {
+	states, ok, err := reader.readKeyStates(timeout)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	deck.mu.Lock()
+	defer deck.mu.Unlock()
+
+	if deck.keyState == nil {
+		deck.keyState = make([]bool, deck.keyCount)
+	}
+
+	now := time.Now()
+	chorded := false
+
+	for key := byte(0); int(key) < len(states); key++ {
+		was := deck.keyState[key]
+		is := states[key]
+		if was == is {
+			continue
+		}
+		deck.keyState[key] = is
+
+		if is {
+			deck.dispatchKey(255)
+			deck.dispatchKey(key)
+			deck.emit(KeyEvent{Type: KeyDown, Key: key})
+			deck.notifyActivity()
+
+			for other, since := range deck.heldSince {
+				if other != key && now.Sub(since) <= deck.chordWindow {
+					chorded = true
+				}
+			}
+			deck.heldSince[key] = now
+		} else {
+			delete(deck.heldSince, key)
+			deck.emit(KeyEvent{Type: KeyUp, Key: key})
+		}
+	}
+
+	if chorded {
+		deck.emit(KeyEvent{Type: KeyChord, Keys: deck.heldKeysLocked()})
+	}
+
+	return nil
+}
+
+// heldKeysLocked returns the keys currently held; callers must hold deck.mu.
+func (deck *streamDeckBase) heldKeysLocked() []byte {
+	keys := make([]byte, 0, len(deck.heldSince))
+	for key := range deck.heldSince {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// emit delivers event to the Events() channel if one is active, dropping it
+// rather than blocking if the consumer isn't keeping up. Callers must hold
+// deck.mu.
+func (deck *streamDeckBase) emit(event KeyEvent) {
+	if deck.events == nil {
+		return
+	}
+	select {
+	case deck.events <- event:
+	default:
+	}
+}
+
+// notifyActivity pings the idle-timeout goroutine started by
+// SetIdleTimeout, if one is running. Callers must hold deck.mu.
+func (deck *streamDeckBase) notifyActivity() {
+	if deck.activity == nil {
+		return
+	}
+	select {
+	case deck.activity <- struct{}{}:
+	default:
+	}
+}
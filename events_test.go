@@ -0,0 +1,128 @@
+// ***************************************************************************
+//
+//  Copyright 2019 David (Dizzy) Smith, dizzyd@dizzyd.com
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+// ***************************************************************************
+package streamdeck
+
+import "testing"
+
+// fixedStatesReader is a keyStateReader that hands pollOnce whatever states
+// slice the test last set, ignoring timeout.
+type fixedStatesReader struct {
+	states []bool
+}
+
+func (r *fixedStatesReader) readKeyStates(timeout int) ([]bool, bool, error) {
+	return r.states, true, nil
+}
+
+func newTestDeck(keyCount byte) *streamDeckBase {
+	return newStreamDeckBase(nil, keyCount, keyCount, KeyDimensions{Width: 10, Height: 10}, ImageFormatBMP)
+}
+
+func drainEvent(t *testing.T, events chan KeyEvent) KeyEvent {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	default:
+		t.Fatal("expected an event, got none")
+		return KeyEvent{}
+	}
+}
+
+func TestPollOnceKeyDownThenUp(t *testing.T) {
+	deck := newTestDeck(2)
+	deck.events = make(chan KeyEvent, 8)
+	reader := &fixedStatesReader{states: []bool{true, false}}
+
+	if err := deck.pollOnce(reader, 0); err != nil {
+		t.Fatalf("pollOnce: %v", err)
+	}
+	ev := drainEvent(t, deck.events)
+	if ev.Type != KeyDown || ev.Key != 0 {
+		t.Fatalf("expected KeyDown(0), got %+v", ev)
+	}
+
+	reader.states = []bool{false, false}
+	if err := deck.pollOnce(reader, 0); err != nil {
+		t.Fatalf("pollOnce: %v", err)
+	}
+	ev = drainEvent(t, deck.events)
+	if ev.Type != KeyUp || ev.Key != 0 {
+		t.Fatalf("expected KeyUp(0), got %+v", ev)
+	}
+
+	select {
+	case ev := <-deck.events:
+		t.Fatalf("unexpected extra event %+v", ev)
+	default:
+	}
+}
+
+func TestPollOnceNoTransitionNoEvent(t *testing.T) {
+	deck := newTestDeck(1)
+	deck.events = make(chan KeyEvent, 8)
+	reader := &fixedStatesReader{states: []bool{false}}
+
+	if err := deck.pollOnce(reader, 0); err != nil {
+		t.Fatalf("pollOnce: %v", err)
+	}
+
+	select {
+	case ev := <-deck.events:
+		t.Fatalf("expected no event for a steady released state, got %+v", ev)
+	default:
+	}
+}
+
+func TestPollOnceChord(t *testing.T) {
+	deck := newTestDeck(3)
+	deck.events = make(chan KeyEvent, 8)
+	reader := &fixedStatesReader{states: []bool{true, true, false}}
+
+	if err := deck.pollOnce(reader, 0); err != nil {
+		t.Fatalf("pollOnce: %v", err)
+	}
+
+	var downs []byte
+	var chord *KeyEvent
+	for i := 0; i < 3; i++ {
+		select {
+		case ev := <-deck.events:
+			switch ev.Type {
+			case KeyDown:
+				downs = append(downs, ev.Key)
+			case KeyChord:
+				e := ev
+				chord = &e
+			default:
+				t.Fatalf("unexpected event %+v", ev)
+			}
+		default:
+			t.Fatalf("expected 3 events, only got %d", i)
+		}
+	}
+
+	if len(downs) != 2 || downs[0] != 0 || downs[1] != 1 {
+		t.Fatalf("expected KeyDown for 0 then 1, got %v", downs)
+	}
+	if chord == nil {
+		t.Fatal("expected a KeyChord event alongside the two KeyDowns")
+	}
+	if len(chord.Keys) != 2 {
+		t.Fatalf("expected chord to report 2 held keys, got %v", chord.Keys)
+	}
+}
@@ -0,0 +1,149 @@
+// ***************************************************************************
+//
+//  Copyright 2019 David (Dizzy) Smith, dizzyd@dizzyd.com
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+// ***************************************************************************
+package streamdeck
+
+import (
+	"image"
+	"time"
+)
+
+// imageSetter is implemented by each model; setIdleTimeout uses it to blank
+// and restore key images without a model-specific hook.
+type imageSetter interface {
+	SetKeyImageRaw(key byte, img image.Image) error
+}
+
+// rememberImage records img as the last image pushed to key, so it can be
+// restored after an idle blank. A nil img means key was explicitly cleared,
+// so it's dropped from the remembered set rather than restored later.
+func (deck *streamDeckBase) rememberImage(key byte, img image.Image) {
+	deck.mu.Lock()
+	defer deck.mu.Unlock()
+
+	if img == nil {
+		delete(deck.lastImages, key)
+		return
+	}
+
+	if deck.lastImages == nil {
+		deck.lastImages = make(map[byte]image.Image)
+	}
+	deck.lastImages[key] = img
+}
+
+// setIdleTimeout is the shared implementation behind each model's
+// SetIdleTimeout. reader/setter are the concrete device, passed through so
+// the background goroutine can poll key state and blank/restore images the
+// same way startEventLoop does for Events(). Any idle goroutine started by
+// a previous call is stopped before the new one starts, so only one idle
+// timeout is ever active at a time.
+func (deck *streamDeckBase) setIdleTimeout(reader keyStateReader, setter imageSetter, d time.Duration, onIdle func()) {
+	deck.startEventLoop(reader)
+
+	deck.mu.Lock()
+	if deck.activity == nil {
+		deck.activity = make(chan struct{}, 1)
+	}
+	activity := deck.activity
+	stopCh := deck.stopCh
+
+	prevStopCh := deck.idleStopCh
+	prevDoneCh := deck.idleDoneCh
+
+	idleStopCh := make(chan struct{})
+	idleDoneCh := make(chan struct{})
+	deck.idleStopCh = idleStopCh
+	deck.idleDoneCh = idleDoneCh
+	deck.mu.Unlock()
+
+	if prevStopCh != nil {
+		close(prevStopCh)
+		<-prevDoneCh
+	}
+
+	go func() {
+		defer close(idleDoneCh)
+
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		idle := false
+
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-idleStopCh:
+				return
+			case <-activity:
+				if idle {
+					idle = false
+					deck.restoreImages(setter)
+				}
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(d)
+			case <-timer.C:
+				if !idle {
+					idle = true
+					deck.blankImages(setter)
+					if onIdle != nil {
+						onIdle()
+					}
+				}
+				timer.Reset(d)
+			}
+		}
+	}()
+}
+
+// blankImages clears every key on the device, not just ones with a
+// remembered image. SetKeyImageRaw(key, nil) deletes key from lastImages via
+// rememberImage as it goes, so the pre-blank set is snapshotted first and
+// restored once blanking is done, letting restoreImages put real images
+// back later without resurrecting a key the caller explicitly cleared.
+func (deck *streamDeckBase) blankImages(setter imageSetter) {
+	deck.mu.Lock()
+	keyCount := deck.keyCount
+	saved := make(map[byte]image.Image, len(deck.lastImages))
+	for key, img := range deck.lastImages {
+		saved[key] = img
+	}
+	deck.mu.Unlock()
+
+	for key := byte(0); key < keyCount; key++ {
+		_ = setter.SetKeyImageRaw(key, nil)
+	}
+
+	deck.mu.Lock()
+	deck.lastImages = saved
+	deck.mu.Unlock()
+}
+
+// restoreImages re-sends the last remembered image for every key.
+func (deck *streamDeckBase) restoreImages(setter imageSetter) {
+	deck.mu.Lock()
+	images := make(map[byte]image.Image, len(deck.lastImages))
+	for key, img := range deck.lastImages {
+		images[key] = img
+	}
+	deck.mu.Unlock()
+
+	for key, img := range images {
+		_ = setter.SetKeyImageRaw(key, img)
+	}
+}
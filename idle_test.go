@@ -0,0 +1,162 @@
+// ***************************************************************************
+//
+//  Copyright 2019 David (Dizzy) Smith, dizzyd@dizzyd.com
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+// ***************************************************************************
+package streamdeck
+
+import (
+	"image"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// noopReader is a keyStateReader that never reports a key transition; it
+// exists only so startEventLoop, which setIdleTimeout runs on top of, has
+// something to poll.
+type noopReader struct{}
+
+func (noopReader) readKeyStates(timeout int) ([]bool, bool, error) {
+	time.Sleep(time.Millisecond)
+	return nil, false, nil
+}
+
+// recordingSetter captures every SetKeyImageRaw call made against it.
+type recordingSetter struct {
+	mu    sync.Mutex
+	calls []recordedCall
+}
+
+type recordedCall struct {
+	key byte
+	img image.Image
+}
+
+func (s *recordingSetter) SetKeyImageRaw(key byte, img image.Image) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, recordedCall{key: key, img: img})
+	return nil
+}
+
+func (s *recordingSetter) callsFor(key byte) []recordedCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []recordedCall
+	for _, c := range s.calls {
+		if c.key == key {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func waitUntil(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("condition not met before timeout")
+}
+
+func TestSetIdleTimeoutBlanksAllKeysAndRestoresImaged(t *testing.T) {
+	deck := newTestDeck(2)
+	defer deck.stopEventLoop()
+
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	deck.rememberImage(0, img)
+	// key 1 never had an image set.
+
+	setter := &recordingSetter{}
+	var idled int32
+	deck.setIdleTimeout(noopReader{}, setter, 20*time.Millisecond, func() {
+		atomic.AddInt32(&idled, 1)
+	})
+
+	waitUntil(t, time.Second, func() bool { return atomic.LoadInt32(&idled) > 0 })
+
+	if calls := setter.callsFor(0); len(calls) == 0 || calls[len(calls)-1].img != nil {
+		t.Fatalf("expected key 0 to be blanked, calls: %+v", calls)
+	}
+	if calls := setter.callsFor(1); len(calls) == 0 || calls[len(calls)-1].img != nil {
+		t.Fatalf("expected key 1 (never imaged) to also be blanked, calls: %+v", calls)
+	}
+
+	deck.mu.Lock()
+	deck.notifyActivity()
+	deck.mu.Unlock()
+
+	waitUntil(t, time.Second, func() bool {
+		calls := setter.callsFor(0)
+		return len(calls) > 0 && calls[len(calls)-1].img == img
+	})
+
+	if calls := setter.callsFor(1); len(calls) != 1 {
+		t.Fatalf("key 1 was never imaged, expected no restore call, got %+v", calls)
+	}
+}
+
+func TestSetIdleTimeoutDoesNotRestoreExplicitlyClearedKey(t *testing.T) {
+	deck := newTestDeck(1)
+	defer deck.stopEventLoop()
+
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	deck.rememberImage(0, img)
+	deck.rememberImage(0, nil) // simulate ClearKeyImage(0)
+
+	setter := &recordingSetter{}
+	deck.setIdleTimeout(noopReader{}, setter, 20*time.Millisecond, nil)
+
+	waitUntil(t, time.Second, func() bool { return len(setter.callsFor(0)) > 0 })
+
+	deck.mu.Lock()
+	deck.notifyActivity()
+	deck.mu.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+
+	for _, c := range setter.callsFor(0) {
+		if c.img != nil {
+			t.Fatalf("explicitly-cleared key was restored with an image: %+v", c)
+		}
+	}
+}
+
+func TestSetIdleTimeoutReplacesPreviousTimer(t *testing.T) {
+	deck := newTestDeck(1)
+	defer deck.stopEventLoop()
+
+	setter := &recordingSetter{}
+	var firstIdled, secondIdled int32
+
+	deck.setIdleTimeout(noopReader{}, setter, time.Hour, func() {
+		atomic.AddInt32(&firstIdled, 1)
+	})
+	deck.setIdleTimeout(noopReader{}, setter, 20*time.Millisecond, func() {
+		atomic.AddInt32(&secondIdled, 1)
+	})
+
+	waitUntil(t, time.Second, func() bool { return atomic.LoadInt32(&secondIdled) > 0 })
+
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&firstIdled) != 0 {
+		t.Fatalf("replaced idle timer fired; expected only the replacement to run")
+	}
+}
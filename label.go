@@ -0,0 +1,88 @@
+// ***************************************************************************
+//
+//  Copyright 2019 David (Dizzy) Smith, dizzyd@dizzyd.com
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+// ***************************************************************************
+package streamdeck
+
+import (
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+)
+
+// LabelOptions controls how SetKeyLabel rasterizes text into a key image.
+// Any zero-valued field falls back to a sensible default.
+type LabelOptions struct {
+	// Font is the face used to draw Text; defaults to basicfont.Face7x13
+	Font font.Face
+
+	// Foreground is the text color; defaults to white
+	Foreground color.Color
+
+	// Background is the fill color behind the text; defaults to black.
+	// Ignored if BackgroundImage is set.
+	Background color.Color
+
+	// BackgroundImage, if set, is composited behind the text instead of
+	// Background. It is scaled to fill the key's dimensions.
+	BackgroundImage image.Image
+}
+
+// renderLabel rasterizes text into an image sized for dims, using opts to
+// control the font, colors and optional background image.
+func renderLabel(dims KeyDimensions, text string, opts LabelOptions) image.Image {
+	face := opts.Font
+	if face == nil {
+		face = basicfont.Face7x13
+	}
+
+	fg := opts.Foreground
+	if fg == nil {
+		fg = color.White
+	}
+
+	bg := opts.Background
+	if bg == nil {
+		bg = color.Black
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, dims.Width, dims.Height))
+
+	if opts.BackgroundImage != nil {
+		draw.CatmullRom.Scale(canvas, canvas.Bounds(), opts.BackgroundImage, opts.BackgroundImage.Bounds(), draw.Src, nil)
+	} else {
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	}
+
+	metrics := face.Metrics()
+	advance := font.MeasureString(face, text)
+	dot := fixed.Point26_6{
+		X: (fixed.I(dims.Width) - advance) / 2,
+		Y: (fixed.I(dims.Height) + metrics.Ascent - metrics.Descent) / 2,
+	}
+
+	drawer := &font.Drawer{
+		Dst:  canvas,
+		Src:  image.NewUniform(fg),
+		Face: face,
+		Dot:  dot,
+	}
+	drawer.DrawString(text)
+
+	return canvas
+}
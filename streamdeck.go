@@ -20,11 +20,20 @@ import (
 	"fmt"
 	"github.com/dizzyd/hid"
 	"github.com/pkg/errors"
+	"image"
+	"sync"
+	"time"
 )
 
 const vendor = 4057
 
-const streamDeck15Id = 96
+const (
+	streamDeckOriginalId = 96
+	streamDeckMiniId     = 99
+	streamDeckXLId       = 108
+	streamDeckMK2Id      = 128
+	streamDeckPlusId     = 132
+)
 
 var ErrUnknownDevice = errors.New("unknown device")
 var ErrNoDevices = errors.New("no devices found")
@@ -32,6 +41,20 @@ var ErrInvalidKey = errors.New("invalid key")
 
 type KeyPressFn func(key byte) bool
 
+// ImageFormat identifies the pixel encoding a device expects for key images.
+type ImageFormat int
+
+const (
+	ImageFormatBMP ImageFormat = iota
+	ImageFormatJPEG
+)
+
+// KeyDimensions describes the pixel width and height of a single key image.
+type KeyDimensions struct {
+	Width  int
+	Height int
+}
+
 // StreamDeck provides an interface for controlling a deck. Keys are
 // zero-based, left-to-right, top-to-bottom
 type StreamDeck interface {
@@ -50,9 +73,15 @@ type StreamDeck interface {
 	// Remove the handler for a given key
 	ClearKeyHandler(key byte) error
 
-	// Set the image on a given key; only PNG is currently supported
+	// Set the image on a given key by loading it from filename
 	SetKeyImage(key byte, filename string) error
 
+	// Set the image on a given key from an in-memory image.Image
+	SetKeyImageRaw(key byte, img image.Image) error
+
+	// Render text as a key image and set it on a given key
+	SetKeyLabel(key byte, text string, opts LabelOptions) error
+
 	// Clear the image on a given key
 	ClearKeyImage(key byte) error
 
@@ -60,12 +89,68 @@ type StreamDeck interface {
 	// zero as a timeout for non-blocking behaviour, use -1 for blocking until
 	// a key is pressed.
 	ProcessEvents(timeout int) error
+
+	// Events starts a background read loop (if not already running) and
+	// returns a channel of key-down/key-up/chord events. The legacy handler
+	// API above continues to work alongside it.
+	Events() <-chan KeyEvent
+
+	// Close stops the background loop started by Events, if running.
+	Close() error
+
+	// SetChordWindow sets how close in time multiple key-downs must land to
+	// be reported as a KeyChord event. The default is 150ms.
+	SetChordWindow(d time.Duration)
+
+	// SetBrightness sets the panel brightness as a percentage (0-100).
+	SetBrightness(percent uint8) error
+
+	// GetSerialNumber reads the device's serial number via a feature report.
+	GetSerialNumber() (string, error)
+
+	// GetFirmwareVersion reads the device's firmware version via a feature
+	// report.
+	GetFirmwareVersion() (string, error)
+
+	// SetIdleTimeout starts a background timer, built on the event loop,
+	// that blanks every key after d has elapsed with no key activity,
+	// calling onIdle once when that happens. Key images are restored
+	// automatically on the next press. Calling it again replaces the
+	// previous timeout.
+	SetIdleTimeout(d time.Duration, onIdle func())
+
+	// KeyCount returns the number of physical keys on the device
+	KeyCount() byte
+
+	// KeyDimensions returns the pixel width/height expected for a key image
+	KeyDimensions() KeyDimensions
+
+	// ImageFormat returns the image encoding expected for key images
+	ImageFormat() ImageFormat
 }
 
 // Base structure for all StreamDeck implementations
 type streamDeckBase struct {
 	device   *hid.Device
 	handlers map[byte]KeyPressFn
+
+	keyCount      byte
+	columns       byte
+	keyDimensions KeyDimensions
+	imageFormat   ImageFormat
+
+	mu          sync.Mutex
+	keyState    []bool
+	heldSince   map[byte]time.Time
+	chordWindow time.Duration
+	events      chan KeyEvent
+	stopCh      chan struct{}
+	wg          sync.WaitGroup
+
+	lastImages map[byte]image.Image
+	activity   chan struct{}
+	idleStopCh chan struct{}
+	idleDoneCh chan struct{}
 }
 
 // OpenStreamDeck finds the first available deck and returns an instance of the StreamDeck interface
@@ -78,8 +163,21 @@ func OpenStreamDeck() (StreamDeck, error) {
 		}
 
 		switch deviceInfo.ProductID {
-		case streamDeck15Id:
-			return &streamDeck15{streamDeckBase: newStreamDeckBase(device)}, nil
+		case streamDeckOriginalId:
+			return &streamDeckOriginal{streamDeckBase: newStreamDeckBase(
+				device, 15, 5, KeyDimensions{Width: 72, Height: 72}, ImageFormatBMP)}, nil
+		case streamDeckMiniId:
+			return &streamDeckMini{streamDeckBase: newStreamDeckBase(
+				device, 6, 3, KeyDimensions{Width: 80, Height: 80}, ImageFormatBMP)}, nil
+		case streamDeckXLId:
+			return &streamDeckXL{streamDeckBase: newStreamDeckBase(
+				device, 32, 8, KeyDimensions{Width: 96, Height: 96}, ImageFormatJPEG)}, nil
+		case streamDeckMK2Id:
+			return &streamDeckMK2{streamDeckBase: newStreamDeckBase(
+				device, 15, 5, KeyDimensions{Width: 72, Height: 72}, ImageFormatJPEG)}, nil
+		case streamDeckPlusId:
+			return &streamDeckPlus{streamDeckBase: newStreamDeckBase(
+				device, 4, 4, KeyDimensions{Width: 120, Height: 120}, ImageFormatJPEG)}, nil
 		default:
 			return nil, errors.New(fmt.Sprintf("unknown device %d-%d", deviceInfo.VendorID, deviceInfo.ProductID))
 		}
@@ -88,9 +186,104 @@ func OpenStreamDeck() (StreamDeck, error) {
 	return nil, ErrNoDevices
 }
 
-func newStreamDeckBase(device *hid.Device) *streamDeckBase {
+func newStreamDeckBase(device *hid.Device, keyCount byte, columns byte, dimensions KeyDimensions, format ImageFormat) *streamDeckBase {
 	return &streamDeckBase{
-		device:   device,
-		handlers: make(map[byte]KeyPressFn),
+		device:        device,
+		handlers:      make(map[byte]KeyPressFn),
+		keyCount:      keyCount,
+		columns:       columns,
+		keyDimensions: dimensions,
+		imageFormat:   format,
+		heldSince:     make(map[byte]time.Time),
+		chordWindow:   defaultChordWindow,
+	}
+}
+
+// Set the handler for all keys
+func (deck *streamDeckBase) SetGlobalKeyHandler(fn KeyPressFn) {
+	deck.handlers[255] = fn
+}
+
+// Clear the handler for all keys
+func (deck *streamDeckBase) ClearGlobalKeyHandler() {
+	delete(deck.handlers, 255)
+}
+
+func (deck *streamDeckBase) SetKeyHandler(key byte, fn KeyPressFn) error {
+	if key >= deck.keyCount {
+		return errors.WithStack(ErrInvalidKey)
+	}
+	deck.handlers[key] = fn
+	return nil
+}
+
+func (deck *streamDeckBase) ClearKeyHandler(key byte) error {
+	if key >= deck.keyCount {
+		return errors.WithStack(ErrInvalidKey)
+	}
+	delete(deck.handlers, key)
+	return nil
+}
+
+func (deck *streamDeckBase) KeyCount() byte {
+	return deck.keyCount
+}
+
+func (deck *streamDeckBase) KeyDimensions() KeyDimensions {
+	return deck.keyDimensions
+}
+
+func (deck *streamDeckBase) ImageFormat() ImageFormat {
+	return deck.imageFormat
+}
+
+func (deck *streamDeckBase) dispatchKey(key byte) {
+	handler, exists := deck.handlers[key]
+	if exists {
+		if !handler(key) {
+			delete(deck.handlers, key)
+		}
+	}
+}
+
+// invertKeyOrId maps between the device's right-to-left key numbering and
+// the left-to-right numbering exposed by this package, using the device's
+// column count.
+func (deck *streamDeckBase) invertKeyOrId(value byte) byte {
+	col := value % deck.columns
+	return (value - col) + ((deck.columns - 1) - col)
+}
+
+// writeImagePages splits payload into maxPayload-sized, zero-padded chunks
+// and writes each as a HID report, using headerFn to build the per-page
+// report header. headerFn receives the zero-based page index and whether
+// this is the final page.
+func (deck *streamDeckBase) writeImagePages(payload []byte, maxPayload int, headerFn func(page int, isLast bool) []byte) error {
+	offset := 0
+	page := 0
+	for {
+		end := offset + maxPayload
+		if end > len(payload) {
+			end = len(payload)
+		}
+		isLast := end >= len(payload)
+
+		chunk := payload[offset:end]
+		if len(chunk) < maxPayload {
+			padded := make([]byte, maxPayload)
+			copy(padded, chunk)
+			chunk = padded
+		}
+
+		if err := writePage(deck.device, headerFn(page, isLast), chunk); err != nil {
+			return errors.Wrapf(err, "failed to write page %d", page)
+		}
+
+		if isLast {
+			return nil
+		}
+
+		offset = end
+		page++
 	}
 }
@@ -0,0 +1,120 @@
+// ***************************************************************************
+//
+//  Copyright 2019 David (Dizzy) Smith, dizzyd@dizzyd.com
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+// ***************************************************************************
+package streamdeck
+
+import (
+	"github.com/pkg/errors"
+	"image"
+	"time"
+)
+
+// miniMaxPagePayload is the number of image bytes sent in each HID report for
+// the Mini, which uses the same paged BMP-in-HID protocol as the Original.
+const miniMaxPagePayload = 1024
+
+// streamDeckMini implements the StreamDeck interface for the 6-key "Mini"
+// device (product id 99): 80x80 BMP key images.
+type streamDeckMini struct {
+	*streamDeckBase
+}
+
+func (deck *streamDeckMini) Reset() error {
+	return deck.device.WriteFeature([]byte{0x0b, 0x63})
+}
+
+func (deck *streamDeckMini) SetBrightness(percent uint8) error {
+	return deck.device.WriteFeature([]byte{0x05, 0x55, 0xaa, 0xd1, 0x01, clampPercent(percent)})
+}
+
+func (deck *streamDeckMini) GetSerialNumber() (string, error) {
+	return readFeatureString(deck.streamDeckBase, originalSerialReportId, originalSerialLength, originalSerialOffset)
+}
+
+func (deck *streamDeckMini) GetFirmwareVersion() (string, error) {
+	return readFeatureString(deck.streamDeckBase, originalFirmwareReportId, originalFirmwareLength, originalFirmwareOffset)
+}
+
+func (deck *streamDeckMini) SetIdleTimeout(d time.Duration, onIdle func()) {
+	deck.setIdleTimeout(deck, deck, d, onIdle)
+}
+
+func (deck *streamDeckMini) SetKeyImage(key byte, filename string) error {
+	img, err := loadImage(filename)
+	if err != nil {
+		return err
+	}
+
+	return deck.SetKeyImageRaw(key, img)
+}
+
+func (deck *streamDeckMini) SetKeyImageRaw(key byte, img image.Image) error {
+	deck.rememberImage(key, img)
+
+	// The deck uses 1-based numbering for images, so we invert the key into an
+	// id and make sure to add one
+	id := deck.invertKeyOrId(key) + 1
+
+	pixels := encodeBMP(img, deck.keyDimensions)
+	header := bmpFileHeader(deck.keyDimensions.Width, deck.keyDimensions.Height)
+	payload := append(header, pixels...)
+
+	return deck.writeImagePages(payload, miniMaxPagePayload, func(page int, isLast bool) []byte {
+		var last byte
+		if isLast {
+			last = 1
+		}
+		return []byte{0x02, 0x01, byte(page), 0x00, last, id, 0x00, 0x00}
+	})
+}
+
+func (deck *streamDeckMini) SetKeyLabel(key byte, text string, opts LabelOptions) error {
+	return deck.SetKeyImageRaw(key, renderLabel(deck.keyDimensions, text, opts))
+}
+
+func (deck *streamDeckMini) ClearKeyImage(key byte) error {
+	return deck.SetKeyImage(key, "")
+}
+
+func (deck *streamDeckMini) ProcessEvents(timeout int) error {
+	return deck.processKeyStates(deck, timeout)
+}
+
+func (deck *streamDeckMini) Events() <-chan KeyEvent {
+	return deck.startEventLoop(deck)
+}
+
+func (deck *streamDeckMini) Close() error {
+	return deck.stopEventLoop()
+}
+
+func (deck *streamDeckMini) readKeyStates(timeout int) ([]bool, bool, error) {
+	report := make([]byte, 7)
+	n, err := deck.device.ReadTimeout(report, timeout)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "error reading key press")
+	}
+	if n == 0 || report[0] != 1 {
+		return nil, false, nil
+	}
+
+	states := make([]bool, deck.keyCount)
+	for id, state := range report[1:] {
+		states[deck.invertKeyOrId(byte(id))] = state == 1
+	}
+
+	return states, true, nil
+}
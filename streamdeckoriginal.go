@@ -0,0 +1,152 @@
+// ***************************************************************************
+//
+//  Copyright 2019 David (Dizzy) Smith, dizzyd@dizzyd.com
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+// ***************************************************************************
+package streamdeck
+
+import (
+	"github.com/pkg/errors"
+	"image"
+	"time"
+)
+
+// streamDeckOriginal implements the StreamDeck interface for the 15-key
+// "Original" device (product id 96): 72x72 BMP key images delivered over a
+// two-page BMP-in-HID protocol.
+type streamDeckOriginal struct {
+	*streamDeckBase
+}
+
+// Original/Mini feature report ids/layout for serial number and firmware
+// version queries.
+const (
+	originalSerialReportId   = 0x03
+	originalSerialLength     = 17
+	originalSerialOffset     = 5
+	originalFirmwareReportId = 0x04
+	originalFirmwareLength   = 17
+	originalFirmwareOffset   = 5
+)
+
+func (deck *streamDeckOriginal) Reset() error {
+	return deck.device.WriteFeature([]byte{0x0b, 0x63})
+}
+
+func (deck *streamDeckOriginal) SetBrightness(percent uint8) error {
+	return deck.device.WriteFeature([]byte{0x05, 0x55, 0xaa, 0xd1, 0x01, clampPercent(percent)})
+}
+
+func (deck *streamDeckOriginal) GetSerialNumber() (string, error) {
+	return readFeatureString(deck.streamDeckBase, originalSerialReportId, originalSerialLength, originalSerialOffset)
+}
+
+func (deck *streamDeckOriginal) GetFirmwareVersion() (string, error) {
+	return readFeatureString(deck.streamDeckBase, originalFirmwareReportId, originalFirmwareLength, originalFirmwareOffset)
+}
+
+func (deck *streamDeckOriginal) SetIdleTimeout(d time.Duration, onIdle func()) {
+	deck.setIdleTimeout(deck, deck, d, onIdle)
+}
+
+func (deck *streamDeckOriginal) SetKeyImage(key byte, filename string) error {
+	img, err := loadImage(filename)
+	if err != nil {
+		return err
+	}
+
+	return deck.SetKeyImageRaw(key, img)
+}
+
+func (deck *streamDeckOriginal) SetKeyImageRaw(key byte, img image.Image) error {
+	deck.rememberImage(key, img)
+
+	// The deck uses 1-based numbering for images, so we invert the key into an
+	// id and make sure to add one
+	id := deck.invertKeyOrId(key) + 1
+
+	imageBytes := encodeBMP(img, deck.keyDimensions)
+
+	// The following headers and constants are taken from:
+	// https://github.com/abcminiuser/python-elgato-streamdeck/blob/master/src/StreamDeck/Devices/StreamDeckOriginal.py#L131
+	// (MIT License)
+	header1 := []byte{
+		0x02, 0x01, 0x01, 0x00, 0x00, id, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x42, 0x4d, 0xf6, 0x3c, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x36, 0x00, 0x00, 0x00, 0x28, 0x00,
+		0x00, 0x00, 0x48, 0x00, 0x00, 0x00, 0x48, 0x00,
+		0x00, 0x00, 0x01, 0x00, 0x18, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0xc0, 0x3c, 0x00, 0x00, 0xc4, 0x0e,
+		0x00, 0x00, 0xc4, 0x0e, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+
+	header2 := []byte{
+		0x02, 0x01, 0x02, 0x00, 0x01, id, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+
+	imageBytesOnPage1 := 2583 * 3
+
+	err := writePage(deck.device, header1, imageBytes[:imageBytesOnPage1])
+	if err != nil {
+		return errors.Wrapf(err, "failed to write page 1")
+	}
+
+	err = writePage(deck.device, header2, imageBytes[imageBytesOnPage1:])
+	if err != nil {
+		return errors.Wrapf(err, "failed to write page 2")
+	}
+
+	return nil
+}
+
+func (deck *streamDeckOriginal) SetKeyLabel(key byte, text string, opts LabelOptions) error {
+	return deck.SetKeyImageRaw(key, renderLabel(deck.keyDimensions, text, opts))
+}
+
+func (deck *streamDeckOriginal) ClearKeyImage(key byte) error {
+	return deck.SetKeyImage(key, "")
+}
+
+func (deck *streamDeckOriginal) ProcessEvents(timeout int) error {
+	return deck.processKeyStates(deck, timeout)
+}
+
+func (deck *streamDeckOriginal) Events() <-chan KeyEvent {
+	return deck.startEventLoop(deck)
+}
+
+func (deck *streamDeckOriginal) Close() error {
+	return deck.stopEventLoop()
+}
+
+func (deck *streamDeckOriginal) readKeyStates(timeout int) ([]bool, bool, error) {
+	report := make([]byte, 16)
+	n, err := deck.device.ReadTimeout(report, timeout)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "error reading key press")
+	}
+	if n == 0 || report[0] != 1 {
+		return nil, false, nil
+	}
+
+	states := make([]bool, deck.keyCount)
+	for id, state := range report[1:] {
+		states[deck.invertKeyOrId(byte(id))] = state == 1
+	}
+
+	return states, true, nil
+}
@@ -0,0 +1,120 @@
+// ***************************************************************************
+//
+//  Copyright 2019 David (Dizzy) Smith, dizzyd@dizzyd.com
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+// ***************************************************************************
+package streamdeck
+
+import (
+	"github.com/pkg/errors"
+	"image"
+	"time"
+)
+
+// plusMaxPagePayload is the number of image bytes sent in each HID report
+// for the Plus's v2 image protocol.
+const plusMaxPagePayload = 1024
+
+// streamDeckPlus implements the StreamDeck interface for the Stream Deck+
+// (product id 132): 4 keys @ 120x120 JPEG, delivered over the same v2 paged
+// image protocol as the XL and MK.2. The Plus also has 8 rotary encoders and
+// a touch LCD strip; those are not yet exposed through this interface, so
+// ProcessEvents only reports button presses/releases for now.
+type streamDeckPlus struct {
+	*streamDeckBase
+}
+
+func (deck *streamDeckPlus) Reset() error {
+	return deck.device.WriteFeature([]byte{0x03, 0x02})
+}
+
+func (deck *streamDeckPlus) SetBrightness(percent uint8) error {
+	return deck.device.WriteFeature([]byte{v2BrightnessReportId, v2BrightnessCommand, clampPercent(percent)})
+}
+
+func (deck *streamDeckPlus) GetSerialNumber() (string, error) {
+	return readFeatureString(deck.streamDeckBase, v2SerialReportId, v2SerialLength, v2SerialOffset)
+}
+
+func (deck *streamDeckPlus) GetFirmwareVersion() (string, error) {
+	return readFeatureString(deck.streamDeckBase, v2FirmwareReportId, v2FirmwareLength, v2FirmwareOffset)
+}
+
+func (deck *streamDeckPlus) SetIdleTimeout(d time.Duration, onIdle func()) {
+	deck.setIdleTimeout(deck, deck, d, onIdle)
+}
+
+func (deck *streamDeckPlus) SetKeyImage(key byte, filename string) error {
+	img, err := loadImage(filename)
+	if err != nil {
+		return err
+	}
+
+	return deck.SetKeyImageRaw(key, img)
+}
+
+func (deck *streamDeckPlus) SetKeyImageRaw(key byte, img image.Image) error {
+	deck.rememberImage(key, img)
+
+	id := deck.invertKeyOrId(key)
+
+	payload, err := encodeJPEG(img, deck.keyDimensions)
+	if err != nil {
+		return err
+	}
+
+	return deck.writeImagePages(payload, plusMaxPagePayload, v2PageHeader(id, len(payload), plusMaxPagePayload))
+}
+
+func (deck *streamDeckPlus) SetKeyLabel(key byte, text string, opts LabelOptions) error {
+	return deck.SetKeyImageRaw(key, renderLabel(deck.keyDimensions, text, opts))
+}
+
+func (deck *streamDeckPlus) ClearKeyImage(key byte) error {
+	return deck.SetKeyImage(key, "")
+}
+
+// plusButtonReportId identifies button-state reports; encoder (0x03) and
+// touch-strip (0x04) reports are ignored for now.
+const plusButtonReportId = 0
+
+func (deck *streamDeckPlus) ProcessEvents(timeout int) error {
+	return deck.processKeyStates(deck, timeout)
+}
+
+func (deck *streamDeckPlus) Events() <-chan KeyEvent {
+	return deck.startEventLoop(deck)
+}
+
+func (deck *streamDeckPlus) Close() error {
+	return deck.stopEventLoop()
+}
+
+func (deck *streamDeckPlus) readKeyStates(timeout int) ([]bool, bool, error) {
+	report := make([]byte, int(deck.keyCount)+2)
+	n, err := deck.device.ReadTimeout(report, timeout)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "error reading key press")
+	}
+	if n == 0 || report[0] != 1 || report[1] != plusButtonReportId {
+		return nil, false, nil
+	}
+
+	states := make([]bool, deck.keyCount)
+	for id, state := range report[2:] {
+		states[deck.invertKeyOrId(byte(id))] = state == 1
+	}
+
+	return states, true, nil
+}
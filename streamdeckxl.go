@@ -0,0 +1,115 @@
+// ***************************************************************************
+//
+//  Copyright 2019 David (Dizzy) Smith, dizzyd@dizzyd.com
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+// ***************************************************************************
+package streamdeck
+
+import (
+	"github.com/pkg/errors"
+	"image"
+	"time"
+)
+
+// xlMaxPagePayload is the number of image bytes sent in each HID report for
+// the XL's "v2" image protocol.
+const xlMaxPagePayload = 1024
+
+// streamDeckXL implements the StreamDeck interface for the 32-key "XL"
+// device (product id 108): 96x96 JPEG key images delivered over the v2
+// paged image protocol (also used by the MK.2 and Plus).
+type streamDeckXL struct {
+	*streamDeckBase
+}
+
+func (deck *streamDeckXL) Reset() error {
+	return deck.device.WriteFeature([]byte{0x03, 0x02})
+}
+
+func (deck *streamDeckXL) SetBrightness(percent uint8) error {
+	return deck.device.WriteFeature([]byte{v2BrightnessReportId, v2BrightnessCommand, clampPercent(percent)})
+}
+
+func (deck *streamDeckXL) GetSerialNumber() (string, error) {
+	return readFeatureString(deck.streamDeckBase, v2SerialReportId, v2SerialLength, v2SerialOffset)
+}
+
+func (deck *streamDeckXL) GetFirmwareVersion() (string, error) {
+	return readFeatureString(deck.streamDeckBase, v2FirmwareReportId, v2FirmwareLength, v2FirmwareOffset)
+}
+
+func (deck *streamDeckXL) SetIdleTimeout(d time.Duration, onIdle func()) {
+	deck.setIdleTimeout(deck, deck, d, onIdle)
+}
+
+func (deck *streamDeckXL) SetKeyImage(key byte, filename string) error {
+	img, err := loadImage(filename)
+	if err != nil {
+		return err
+	}
+
+	return deck.SetKeyImageRaw(key, img)
+}
+
+func (deck *streamDeckXL) SetKeyImageRaw(key byte, img image.Image) error {
+	deck.rememberImage(key, img)
+
+	// Unlike the Original/Mini, v2-protocol devices use 0-based key ids
+	id := deck.invertKeyOrId(key)
+
+	payload, err := encodeJPEG(img, deck.keyDimensions)
+	if err != nil {
+		return err
+	}
+
+	return deck.writeImagePages(payload, xlMaxPagePayload, v2PageHeader(id, len(payload), xlMaxPagePayload))
+}
+
+func (deck *streamDeckXL) SetKeyLabel(key byte, text string, opts LabelOptions) error {
+	return deck.SetKeyImageRaw(key, renderLabel(deck.keyDimensions, text, opts))
+}
+
+func (deck *streamDeckXL) ClearKeyImage(key byte) error {
+	return deck.SetKeyImage(key, "")
+}
+
+func (deck *streamDeckXL) ProcessEvents(timeout int) error {
+	return deck.processKeyStates(deck, timeout)
+}
+
+func (deck *streamDeckXL) Events() <-chan KeyEvent {
+	return deck.startEventLoop(deck)
+}
+
+func (deck *streamDeckXL) Close() error {
+	return deck.stopEventLoop()
+}
+
+func (deck *streamDeckXL) readKeyStates(timeout int) ([]bool, bool, error) {
+	report := make([]byte, int(deck.keyCount)+1)
+	n, err := deck.device.ReadTimeout(report, timeout)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "error reading key press")
+	}
+	if n == 0 || report[0] != 1 {
+		return nil, false, nil
+	}
+
+	states := make([]bool, deck.keyCount)
+	for id, state := range report[1:] {
+		states[deck.invertKeyOrId(byte(id))] = state == 1
+	}
+
+	return states, true, nil
+}
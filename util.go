@@ -17,9 +17,13 @@
 package streamdeck
 
 import (
+	"bytes"
+	"encoding/binary"
 	"github.com/dizzyd/hid"
 	"github.com/pkg/errors"
 	"image"
+	_ "image/gif"
+	"image/jpeg"
 	_ "image/png"
 	"os"
 )
@@ -51,3 +55,88 @@ func loadImage(filename string) (image.Image, error) {
 
 	return img, nil
 }
+
+// encodeBMP converts img into the right-to-left/top-to-bottom BGR byte
+// stream the original-protocol devices expect for key images. If img is nil,
+// an empty (black) image of the given dimensions is returned instead.
+//
+// Streamdeck images are right-to-left/top-to-bottom (BGR representation)
+// while Go images are left-to-right/top-to-bottom (RGBA representation), so
+// we walk scanline-by-scanline, placing the rightmost (max x) pixel first.
+func encodeBMP(img image.Image, dims KeyDimensions) []byte {
+	if img == nil {
+		return make([]byte, dims.Width*dims.Height*3)
+	}
+
+	var imageBytes []byte
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Max.X; x > bounds.Min.X; x-- {
+			r, g, b, _ := img.At(x, y).RGBA()
+			imageBytes = append(imageBytes, byte(b), byte(g), byte(r))
+		}
+	}
+
+	return imageBytes
+}
+
+// bmpFileHeader builds the 54-byte BMP file+DIB header for a width x height,
+// 24-bit BGR image, as used by the BMP-protocol devices.
+func bmpFileHeader(width, height int) []byte {
+	pixelBytes := uint32(width * height * 3)
+
+	header := make([]byte, 54)
+	header[0], header[1] = 'B', 'M'
+	binary.LittleEndian.PutUint32(header[2:6], pixelBytes+54)
+	binary.LittleEndian.PutUint32(header[10:14], 54)
+	binary.LittleEndian.PutUint32(header[14:18], 40)
+	binary.LittleEndian.PutUint32(header[18:22], uint32(width))
+	binary.LittleEndian.PutUint32(header[22:26], uint32(height))
+	binary.LittleEndian.PutUint16(header[26:28], 1)
+	binary.LittleEndian.PutUint16(header[28:30], 24)
+	binary.LittleEndian.PutUint32(header[34:38], pixelBytes)
+	binary.LittleEndian.PutUint32(header[38:42], 3780)
+	binary.LittleEndian.PutUint32(header[42:46], 3780)
+
+	return header
+}
+
+// v2PageHeader builds the per-page report header for the "v2" image
+// protocol shared by the XL, MK.2 and Plus: report id 0x02, command 0x07,
+// the (already inverted) key id, a last-page flag, the payload length for
+// this page, and the zero-based page number.
+func v2PageHeader(id byte, payloadLen, maxPayload int) func(page int, isLast bool) []byte {
+	return func(page int, isLast bool) []byte {
+		var last byte
+		if isLast {
+			last = 1
+		}
+
+		length := maxPayload
+		if remaining := payloadLen - page*maxPayload; remaining < length {
+			length = remaining
+		}
+
+		return []byte{
+			0x02, 0x07, id, last,
+			byte(length), byte(length >> 8),
+			byte(page), byte(page >> 8),
+		}
+	}
+}
+
+// encodeJPEG encodes img as a JPEG, as expected by the v2-protocol devices
+// (MK.2, XL, Plus). If img is nil, a blank black image of the given
+// dimensions is encoded instead.
+func encodeJPEG(img image.Image, dims KeyDimensions) ([]byte, error) {
+	if img == nil {
+		img = image.NewRGBA(image.Rect(0, 0, dims.Width, dims.Height))
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return buf.Bytes(), nil
+}